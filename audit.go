@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// auditEntry is one structured JSON line appended to ~/.cache/heroku/audit.log
+// for every CLI invocation.
+type auditEntry struct {
+	Timestamp string   `json:"timestamp"`
+	User      string   `json:"user"`
+	Args      []string `json:"args"`
+	ExitCode  int      `json:"exit_code"`
+	Duration  float64  `json:"duration_seconds"`
+	Panic     string   `json:"panic,omitempty"`
+}
+
+// redactedFlags are CLI flags whose following value must never reach the
+// audit log.
+var redactedFlags = map[string]bool{
+	"--password": true,
+	"-p":         true,
+}
+
+// redactedEnvKeys mark KEY=VALUE style args (as in `heroku config:set
+// HEROKU_API_KEY=...`) whose value should be redacted because the key names
+// a secret.
+var redactedEnvKeys = map[string]bool{
+	"HEROKU_API_KEY": true,
+	"API_KEY":        true,
+	"PASSWORD":       true,
+}
+
+const redactedPlaceholder = "[REDACTED]"
+
+// auditSessionStart is recorded at process start so auditFinish can compute
+// the command's total duration.
+var auditSessionStart = time.Now()
+
+// auditPanicMsg holds the panic message recorded by handlePanic, if any, so
+// it ends up in the audit entry written by the following Exit() call.
+var auditPanicMsg string
+
+func auditEnabled() bool {
+	return strings.ToLower(os.Getenv("HEROKU_AUDIT")) != "off"
+}
+
+func auditLogPath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".cache", "heroku")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// redactArgs returns a copy of args with known secret flags and KEY=VALUE
+// pairs replaced by a placeholder.
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+	for i, arg := range out {
+		if eq := strings.IndexByte(arg, '='); eq > 0 {
+			key := arg[:eq]
+			if redactedFlags[key] || redactedEnvKeys[strings.ToUpper(key)] {
+				out[i] = key + "=" + redactedPlaceholder
+			}
+			continue
+		}
+		if redactedFlags[arg] && i+1 < len(out) {
+			out[i+1] = redactedPlaceholder
+		}
+	}
+	return out
+}
+
+// RunMain is the program entry point's wrapper: main should be no more than
+//
+//	func main() { RunMain(run) }
+//
+// where run does the real work and returns the process exit code. Routing
+// every completion, success included, through Exit(run()) here is what makes
+// auditFinish's "every invocation" guarantee true — a bare `return` from
+// main, or a command that calls os.Exit directly instead of Exit, would
+// silently leave no audit entry for an otherwise-successful run.
+func RunMain(run func() int) {
+	Exit(run())
+}
+
+// auditFinish appends an audit entry recording this invocation and is called
+// from Exit() so every exit path, including panics, is captured. Since
+// os.Exit never runs deferred functions, this must happen before ExitFn is
+// called rather than via a defer in the program entry.
+func auditFinish(exitCode int) {
+	if !auditEnabled() {
+		return
+	}
+	path, err := auditLogPath()
+	if err != nil {
+		Debugln("could not open audit log:", err)
+		return
+	}
+	username := "unknown"
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	entry := auditEntry{
+		Timestamp: auditSessionStart.UTC().Format(time.RFC3339),
+		User:      username,
+		Args:      redactArgs(os.Args),
+		ExitCode:  exitCode,
+		Duration:  time.Since(auditSessionStart).Seconds(),
+		Panic:     auditPanicMsg,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		Debugln("could not marshal audit entry:", err)
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		Debugln("could not open audit log:", err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, string(data))
+}
+
+// auditTail prints the last n lines of the audit log, backing a `heroku
+// audit tail` style helper command.
+func auditTail(n int) error {
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for _, line := range lines {
+		Println(line)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactArgs(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []string
+		out  []string
+	}{
+		{
+			name: "two-token password flag",
+			in:   []string{"heroku", "auth:login", "--password", "hunter2"},
+			out:  []string{"heroku", "auth:login", "--password", redactedPlaceholder},
+		},
+		{
+			name: "equals-form password flag",
+			in:   []string{"heroku", "auth:login", "--password=hunter2"},
+			out:  []string{"heroku", "auth:login", "--password=" + redactedPlaceholder},
+		},
+		{
+			name: "config:set secret env key",
+			in:   []string{"heroku", "config:set", "HEROKU_API_KEY=abc123"},
+			out:  []string{"heroku", "config:set", "HEROKU_API_KEY=" + redactedPlaceholder},
+		},
+		{
+			name: "non-secret args are untouched",
+			in:   []string{"heroku", "apps", "--app", "my-app"},
+			out:  []string{"heroku", "apps", "--app", "my-app"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := redactArgs(c.in)
+			if !reflect.DeepEqual(got, c.out) {
+				t.Errorf("redactArgs(%v) = %v, want %v", c.in, got, c.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// userConfig is persisted to ~/.heroku/config.json. It's read and written a
+// field at a time so unrelated settings added elsewhere aren't clobbered.
+type userConfig struct {
+	ErrorReportingConsent *bool `json:"error_reporting_consent,omitempty"`
+}
+
+func configPath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".heroku")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+func loadUserConfig() userConfig {
+	var cfg userConfig
+	path, err := configPath()
+	if err != nil {
+		return cfg
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+	// A malformed config shouldn't block the command from running.
+	_ = json.Unmarshal(data, &cfg)
+	return cfg
+}
+
+func saveUserConfig(cfg userConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// hasReportingConsent reports whether the user has already opted in to
+// automated crash reporting, without prompting for a decision. reportError
+// uses this for routine, non-fatal errors — which must never block on
+// stdin — and treats no decision on record as "not consented".
+func hasReportingConsent() bool {
+	cfg := loadUserConfig()
+	return cfg.ErrorReportingConsent != nil && *cfg.ErrorReportingConsent
+}
+
+// errorReportingConsent reports whether the user has opted in to automated
+// crash reporting, prompting once on first crash if no decision is on record.
+func errorReportingConsent() bool {
+	cfg := loadUserConfig()
+	isFirstDecision := cfg.ErrorReportingConsent == nil
+	consent := resolveConsent(cfg.ErrorReportingConsent, promptErrorReportingConsent)
+	if isFirstDecision {
+		cfg.ErrorReportingConsent = &consent
+		if err := saveUserConfig(cfg); err != nil {
+			Debugln("could not save error reporting consent:", err)
+		}
+	}
+	return consent
+}
+
+// resolveConsent returns the recorded decision if one exists, otherwise asks
+// for one via prompt. Split out from errorReportingConsent so the decision
+// logic can be tested without touching the filesystem or stdin.
+func resolveConsent(existing *bool, prompt func() bool) bool {
+	if existing != nil {
+		return *existing
+	}
+	return prompt()
+}
+
+func promptErrorReportingConsent() bool {
+	if !istty() {
+		return false
+	}
+	Errln(" " + yellow(ErrorArrow()) + "    kika/cli can automatically report crashes to help us fix bugs faster.")
+	Errln(" " + yellow(ErrorArrow()) + "    No command arguments or app data are included, only the error and CLI version.")
+	Err(" " + yellow(ErrorArrow()) + "    Enable crash reporting? [y/N] ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	line = strings.TrimSpace(strings.ToLower(line))
+	return line == "y" || line == "yes"
+}
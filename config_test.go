@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestResolveConsent(t *testing.T) {
+	yes, no := true, false
+
+	if got := resolveConsent(&yes, func() bool { t.Fatal("prompt should not be called"); return false }); !got {
+		t.Errorf("resolveConsent(true, ...) = %v, want true", got)
+	}
+
+	if got := resolveConsent(&no, func() bool { t.Fatal("prompt should not be called"); return true }); got {
+		t.Errorf("resolveConsent(false, ...) = %v, want false", got)
+	}
+
+	if got := resolveConsent(nil, func() bool { return true }); !got {
+		t.Errorf("resolveConsent(nil, prompt->true) = %v, want true", got)
+	}
+
+	if got := resolveConsent(nil, func() bool { return false }); got {
+		t.Errorf("resolveConsent(nil, prompt->false) = %v, want false", got)
+	}
+}
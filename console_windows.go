@@ -0,0 +1,34 @@
+// +build windows
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+func init() {
+	ansiOK = enableVirtualTerminal(syscall.Stdout) && enableVirtualTerminal(syscall.Stderr)
+}
+
+// enableVirtualTerminal turns on ENABLE_VIRTUAL_TERMINAL_PROCESSING for the
+// given console handle so ANSI escape sequences render natively, as supported
+// on Windows 10+. It returns false, leaving the handle untouched, on older
+// Windows or when the handle isn't backed by an actual console.
+func enableVirtualTerminal(handle syscall.Handle) bool {
+	var mode uint32
+	if ok, _, _ := procGetConsoleMode.Call(uintptr(handle), uintptr(unsafe.Pointer(&mode))); ok == 0 {
+		return false
+	}
+	mode |= enableVirtualTerminalProcessing
+	ok, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode))
+	return ok != 0
+}
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/ansel1/merry"
-	rollbarAPI "github.com/stvp/rollbar"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -26,8 +25,12 @@ var ExitFn = os.Exit
 // Debugging is HEROKU_DEBUG
 var Debugging = isDebugging()
 
-// Exit just calls os.Exit, but can be mocked out for testing
+// Exit records the audit entry for this invocation, then calls os.Exit (which
+// can be mocked out for testing). The audit write has to happen here, rather
+// than in a deferred call in the program entry, because os.Exit never runs
+// deferred functions.
 func Exit(code int) {
+	auditFinish(code)
 	ExitFn(code)
 }
 
@@ -64,21 +67,19 @@ func Println(a ...interface{}) {
 // Debugln is used to print debugging information
 // It will be added to the logfile in ~/.cache/heroku/error.log and stderr if HEROKU_DEBUG is set.
 func Debugln(a ...interface{}) {
-	if Debugging {
-		fmt.Fprintln(Stderr, a...)
-	}
+	logger.Debugln(a...)
+	fileLogger.Debugln(a...)
 }
 
 // Debugf is used to print debugging information
 // It will be added to the logfile in ~/.cache/heroku/error.log and stderr if HEROKU_DEBUG is set.
 func Debugf(format string, a ...interface{}) {
-	if Debugging {
-		fmt.Fprintf(Stderr, format, a...)
-	}
+	logger.Debugf(format, a...)
+	fileLogger.Debugf(format, a...)
 }
 
 // WarnIfError is a helper that prints out formatted error messages
-// it will emit to rollbar
+// it will emit to the error reporter
 // it does not exit
 func WarnIfError(err error) {
 	if err == nil {
@@ -87,47 +88,68 @@ func WarnIfError(err error) {
 	err = merry.Wrap(err)
 	Warn(err.Error())
 	Debugln(merry.Details(err))
-	rollbar(err, "warning")
+	reportError(err, "warning")
 }
 
-// Warn shows a message with excalamation points prepended to stderr
+// Warn shows a message with an arrow prepended to stderr, and mirrors the
+// plain (unprefixed, uncolored) message to the error log via fileLogger.
+// It's the single sink for warning output: callers shouldn't separately log
+// the same message through logger/fileLogger, or it prints/records twice.
 func Warn(msg string) {
-	prefix := " " + yellow(ErrorArrow) + "    "
 	msg = strings.TrimSpace(msg)
-	msg = strings.Join(strings.Split(msg, "\n"), "\n"+prefix)
-	Errln(prefix + msg)
+	prefix := " " + yellow(ErrorArrow()) + "    "
+	display := strings.Join(strings.Split(msg, "\n"), "\n"+prefix)
+	Errln(prefix + display)
+	fileLogger.Warn(msg)
 }
 
-// Error shows a message with excalamation points prepended to stderr
+// Error shows a message with an arrow prepended to stderr, and mirrors the
+// plain (unprefixed, uncolored) message to the error log via fileLogger.
+// It's the single sink for error output: callers shouldn't separately log
+// the same message through logger/fileLogger, or it prints/records twice.
 func Error(msg string) {
-	prefix := " " + red(ErrorArrow) + "    "
 	msg = strings.TrimSpace(msg)
-	msg = strings.Join(strings.Split(msg, "\n"), "\n"+prefix)
-	Errln(prefix + msg)
+	prefix := " " + red(ErrorArrow()) + "    "
+	display := strings.Join(strings.Split(msg, "\n"), "\n"+prefix)
+	Errln(prefix + display)
+	fileLogger.Error(msg)
 }
 
-// ErrorArrow is the triangle or bang that prefixes errors
-var ErrorArrow = errorArrow()
+// ErrorArrow is the triangle or bang that prefixes errors. It's a function,
+// not a package var, because on Windows its value depends on ansiOK, which
+// isn't known until console_windows.go's init() runs; a var initializer would
+// run first and freeze it at the pre-VT-processing value.
+func ErrorArrow() string {
+	return errorArrow()
+}
 
 func errorArrow() string {
-	if windows() {
+	if windows() && !ansiOK {
 		return "!"
 	}
 	return "▸"
 }
 
+// ansiOK is set on Windows when ENABLE_VIRTUAL_TERMINAL_PROCESSING was
+// successfully turned on for stdout/stderr, meaning the console renders raw
+// ANSI escapes instead of needing the legacy console API. It's always false
+// on other platforms, where ANSI is never in question.
+var ansiOK = false
+
 func must(err error) {
 	if err != nil {
 		panic(err)
 	}
 }
 
-// LogIfError logs out an error if one arises
+// LogIfError logs out an error if one arises. It stays quiet at the default
+// log level, same as the original HEROKU_DEBUG-gated behavior; raise
+// HEROKU_LOG_LEVEL=debug to see it.
 func LogIfError(e error) {
 	if e != nil {
 		Debugln(e.Error())
 		Debugln(string(debug.Stack()))
-		rollbar(e, "info")
+		reportError(e, "info")
 	}
 }
 
@@ -143,14 +165,14 @@ func isDebugging() bool {
 }
 
 func yellow(s string) string {
-	if supportsColor() && !windows() {
+	if supportsColor() && (!windows() || ansiOK) {
 		return "\x1b[33m" + s + "\x1b[39m"
 	}
 	return s
 }
 
 func red(s string) string {
-	if supportsColor() && !windows() {
+	if supportsColor() && (!windows() || ansiOK) {
 		return "\x1b[31m" + s + "\x1b[39m"
 	}
 	return s
@@ -176,6 +198,9 @@ func supportsColor() bool {
 	if os.Getenv("COLOR") == "false" {
 		return false
 	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
 	if os.Getenv("TERM") == "dumb" {
 		return false
 	}
@@ -208,30 +233,8 @@ func handlePanic() {
 		err = merry.Wrap(err)
 		Error(err.Error())
 		Debugln(merry.Details(err))
-		rollbar(err, "error")
+		reportCrash(err)
+		auditPanicMsg = err.Error() + "\n" + string(debug.Stack())
 		Exit(1)
 	}
 }
-
-func rollbar(err error, level string) {
-	if os.Getenv("TESTING") == ONE {
-		return
-	}
-	rollbarAPI.Platform = "client"
-	rollbarAPI.Token = "d40104ae6fa8477dbb6907370231d7d8"
-	rollbarAPI.Environment = Channel
-	rollbarAPI.ErrorWriter = nil
-	rollbarAPI.CodeVersion = GitSHA
-	var cmd string
-	if len(os.Args) > 1 {
-		cmd = os.Args[1]
-	}
-	fields := []*rollbarAPI.Field{
-		{"version", Version},
-		{"os", runtime.GOOS},
-		{"arch", runtime.GOARCH},
-		{"command", cmd},
-	}
-	rollbarAPI.Error(level, err, fields...)
-	rollbarAPI.Wait()
-}
\ No newline at end of file
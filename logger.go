@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// logger is the package-level structured logger backing the console side of
+// Debugln and Debugf. It's configured once in init() from HEROKU_LOG_LEVEL
+// and --log-format, and writes to Stderr so HEROKU_DEBUG/
+// HEROKU_LOG_LEVEL=debug surfaces debug output the same way the old
+// Debugln did.
+var logger = logrus.New()
+
+// fileLogger mirrors Debugln/Debugf and every Warn/Error into
+// ~/.cache/heroku/error.log as JSON, independent of what logger prints to
+// the console. Warn and Error print their own human, arrow-prefixed line to
+// Stderr directly rather than going through logger: routing that
+// ANSI-decorated display string through logrus's formatter both garbled it
+// and, since logger also writes to Stderr, printed it a second time.
+var fileLogger = logrus.New()
+
+func init() {
+	logger.SetOutput(Stderr)
+	logger.SetLevel(logLevel())
+	logger.SetFormatter(logFormatter())
+	RegisterHook(&callerHook{})
+
+	fileLogger.SetLevel(logLevel())
+	fileLogger.SetFormatter(&logrus.JSONFormatter{})
+	fileLogger.AddHook(&callerHook{})
+
+	// Skip touching the real ~/.cache/heroku during tests, mirroring the
+	// TESTING guard reporter.go uses before touching disk/network.
+	if os.Getenv("TESTING") == ONE {
+		fileLogger.SetOutput(ioutil.Discard)
+		return
+	}
+
+	if w, err := newErrorLogWriter(); err != nil {
+		Debugln("could not open error log:", err)
+		fileLogger.SetOutput(ioutil.Discard)
+	} else {
+		fileLogger.SetOutput(w)
+		handleSignal(syscall.SIGHUP, func() {
+			if rerr := w.Rotate(); rerr != nil {
+				Debugln("could not reopen error log:", rerr)
+			}
+		})
+	}
+}
+
+// RegisterHook adds a logrus hook to the console logger, so it fires on
+// every entry logged through Debugln, Debugf, Warn and Error. callerHook
+// registers this way.
+//
+// The error reporter deliberately does not: reportError is called directly
+// from WarnIfError, LogIfError and handlePanic instead, so that narrowing
+// HEROKU_LOG_LEVEL (a verbosity concern) can never silently stop errors from
+// reaching a reporter the user has already consented to (a separate, opt-in
+// concern). See reportError's doc comment in reporter.go.
+func RegisterHook(hook logrus.Hook) {
+	logger.AddHook(hook)
+}
+
+func logLevel() logrus.Level {
+	switch strings.ToLower(os.Getenv("HEROKU_LOG_LEVEL")) {
+	case "trace":
+		return logrus.TraceLevel
+	case "debug":
+		return logrus.DebugLevel
+	case "info":
+		return logrus.InfoLevel
+	case "warn", "warning":
+		return logrus.WarnLevel
+	case "error":
+		return logrus.ErrorLevel
+	case "fatal":
+		return logrus.FatalLevel
+	default:
+		if Debugging {
+			return logrus.DebugLevel
+		}
+		return logrus.InfoLevel
+	}
+}
+
+// logFormatter picks the console formatter based on --log-format, defaulting
+// to logrus's human-readable text output.
+func logFormatter() logrus.Formatter {
+	for _, arg := range os.Args {
+		switch arg {
+		case "--log-format=json":
+			return &logrus.JSONFormatter{}
+		case "--log-format=text":
+			return &logrus.TextFormatter{}
+		}
+	}
+	return &logrus.TextFormatter{}
+}
+
+// newErrorLogWriter opens ~/.cache/heroku/error.log through lumberjack so it
+// rotates on size and can be re-opened on SIGHUP.
+func newErrorLogWriter() (*lumberjack.Logger, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(home, ".cache", "heroku")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &lumberjack.Logger{
+		Filename:   filepath.Join(dir, "error.log"),
+		MaxSize:    5, // megabytes
+		MaxBackups: 3,
+	}, nil
+}
+
+// callerHook annotates each entry with the file:line of the command that
+// logged it and the subcommand being run, the logrus.Logger.SetReportCaller
+// equivalent scoped to this CLI's own call sites rather than logrus's.
+type callerHook struct{}
+
+func (h *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *callerHook) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data["caller"]; !ok {
+		if file, line, ok := callSite(); ok {
+			entry.Data["caller"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+		}
+	}
+	if _, ok := entry.Data["command"]; !ok && len(os.Args) > 1 {
+		entry.Data["command"] = os.Args[1]
+	}
+	return nil
+}
+
+// callSite walks the goroutine's stack past logrus's own internals (entry
+// logging, level methods, hook dispatch) and returns the file:line of the
+// first frame outside the logrus package. That's whichever of this CLI's own
+// functions invoked logrus, so unlike a fixed runtime.Caller depth it's
+// correct regardless of how many frames logrus itself happens to use to
+// get there.
+func callSite() (file string, line int, ok bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, callSite, and callerHook.Fire
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/sirupsen/logrus.") {
+			return frame.File, frame.Line, true
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogLevel(t *testing.T) {
+	cases := []struct {
+		env  string
+		want logrus.Level
+	}{
+		{"trace", logrus.TraceLevel},
+		{"debug", logrus.DebugLevel},
+		{"info", logrus.InfoLevel},
+		{"warn", logrus.WarnLevel},
+		{"warning", logrus.WarnLevel},
+		{"error", logrus.ErrorLevel},
+		{"fatal", logrus.FatalLevel},
+		{"", logrus.InfoLevel},
+		{"bogus", logrus.InfoLevel},
+	}
+
+	old := os.Getenv("HEROKU_LOG_LEVEL")
+	defer os.Setenv("HEROKU_LOG_LEVEL", old)
+
+	for _, c := range cases {
+		os.Setenv("HEROKU_LOG_LEVEL", c.env)
+		if got := logLevel(); got != c.want {
+			t.Errorf("logLevel() with HEROKU_LOG_LEVEL=%q = %v, want %v", c.env, got, c.want)
+		}
+	}
+}
+
+func TestLogFormatter(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"heroku", "apps", "--log-format=json"}
+	if _, ok := logFormatter().(*logrus.JSONFormatter); !ok {
+		t.Errorf("logFormatter() with --log-format=json did not return a JSONFormatter")
+	}
+
+	os.Args = []string{"heroku", "apps", "--log-format=text"}
+	if _, ok := logFormatter().(*logrus.TextFormatter); !ok {
+		t.Errorf("logFormatter() with --log-format=text did not return a TextFormatter")
+	}
+
+	os.Args = []string{"heroku", "apps"}
+	if _, ok := logFormatter().(*logrus.TextFormatter); !ok {
+		t.Errorf("logFormatter() with no flag did not default to a TextFormatter")
+	}
+}
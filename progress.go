@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// barWidth is the number of characters used to render a Bar's filled track.
+const barWidth = 30
+
+// Bar is a single progress bar created with NewBar and driven with
+// Increment/Finish. It redraws in place with carriage returns when the
+// terminal is a TTY and colors are enabled, degrading to one Printf line per
+// percentage-point change otherwise so output stays sane when piped or
+// running in CI. All output goes through Print/Printf so tests can assert it
+// via the Stdout indirection.
+type Bar struct {
+	total    int64
+	current  int64
+	label    string
+	live     bool
+	mu       sync.Mutex
+	lastLine string
+}
+
+// NewBar creates a Bar that will track progress toward total, shown under label.
+func NewBar(total int64, label string) *Bar {
+	return &Bar{
+		total: total,
+		label: label,
+		live:  istty() && supportsColor(),
+	}
+}
+
+// Increment advances the bar by n and redraws it.
+func (b *Bar) Increment(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current += n
+	b.draw()
+}
+
+// Finish completes the bar and leaves the cursor on its own line.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = b.total
+	b.draw()
+	Println()
+}
+
+func (b *Bar) draw() {
+	var pct float64
+	if b.total > 0 {
+		pct = float64(b.current) / float64(b.total)
+	}
+	if pct > 1 {
+		pct = 1
+	}
+	filled := int(pct * barWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	line := fmt.Sprintf("%s [%s] %3d%%", b.label, bar, int(pct*100))
+	if line == b.lastLine {
+		return
+	}
+	b.lastLine = line
+	if b.live {
+		Printf("\r%s", line)
+	} else {
+		Println(line)
+	}
+}
+
+// Live is a multi-line updater that redraws a fixed block of lines in place,
+// for commands that report on several concurrent streams at once (e.g. a
+// release rollout across dynos). It degrades to appending a line per update
+// when output isn't a color-capable TTY.
+type Live struct {
+	mu        sync.Mutex
+	lines     []string
+	lastLines []string
+	drawn     bool
+	live      bool
+}
+
+// NewLive creates a Live updater with n initially blank lines.
+func NewLive(n int) *Live {
+	return &Live{
+		lines:     make([]string, n),
+		lastLines: make([]string, n),
+		live:      istty() && supportsColor(),
+	}
+}
+
+// Set updates line i and redraws the block.
+func (l *Live) Set(i int, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines[i] = line
+	l.draw()
+}
+
+// Finish leaves the block's final state in place.
+func (l *Live) Finish() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.live {
+		return
+	}
+	Println()
+}
+
+func (l *Live) draw() {
+	if !l.live {
+		for i, line := range l.lines {
+			if line == l.lastLines[i] {
+				continue
+			}
+			l.lastLines[i] = line
+			Println(fmt.Sprintf("%d: %s", i, line))
+		}
+		return
+	}
+	if l.drawn {
+		Printf("\x1b[%dA", len(l.lines))
+	}
+	l.drawn = true
+	for _, line := range l.lines {
+		Printf("\r\x1b[K%s\n", line)
+	}
+}
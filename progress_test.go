@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func withCapturedStdout(t *testing.T, fn func(*bytes.Buffer)) {
+	old := Stdout
+	var buf bytes.Buffer
+	Stdout = &buf
+	defer func() { Stdout = old }()
+	fn(&buf)
+}
+
+func TestBarDrawNonTTY(t *testing.T) {
+	withCapturedStdout(t, func(buf *bytes.Buffer) {
+		bar := &Bar{total: 4, label: "fetching"}
+		bar.Increment(2)
+		bar.Increment(2)
+
+		out := buf.String()
+		if !strings.Contains(out, "fetching") {
+			t.Errorf("output missing label: %q", out)
+		}
+		if !strings.Contains(out, " 50%") {
+			t.Errorf("output missing 50%% line: %q", out)
+		}
+		if !strings.Contains(out, "100%") {
+			t.Errorf("output missing 100%% line: %q", out)
+		}
+		if strings.Contains(out, "\r") {
+			t.Errorf("non-tty output should not carriage-return redraw: %q", out)
+		}
+	})
+}
+
+func TestBarDrawSkipsUnchangedLines(t *testing.T) {
+	withCapturedStdout(t, func(buf *bytes.Buffer) {
+		bar := &Bar{total: 100, label: "fetching"}
+		bar.Increment(1)
+		before := buf.Len()
+		bar.Increment(0)
+		if buf.Len() != before {
+			t.Errorf("redraw with no percentage change should not print again, wrote %q", buf.String()[before:])
+		}
+	})
+}
+
+func TestLiveDrawNonTTY(t *testing.T) {
+	withCapturedStdout(t, func(buf *bytes.Buffer) {
+		live := &Live{lines: make([]string, 2), lastLines: make([]string, 2)}
+		live.Set(0, "web.1: up")
+		live.Set(1, "worker.1: starting")
+
+		out := buf.String()
+		if !strings.Contains(out, "web.1: up") || !strings.Contains(out, "worker.1: starting") {
+			t.Errorf("output missing line content: %q", out)
+		}
+		if strings.Contains(out, "\x1b[") {
+			t.Errorf("non-tty output should not contain cursor-movement escapes: %q", out)
+		}
+	})
+}
+
+func TestLiveDrawNonTTYOnlyPrintsChangedLine(t *testing.T) {
+	withCapturedStdout(t, func(buf *bytes.Buffer) {
+		live := &Live{lines: make([]string, 2), lastLines: make([]string, 2)}
+		live.Set(0, "web.1: up")
+
+		before := buf.Len()
+		live.Set(1, "worker.1: starting")
+		added := buf.String()[before:]
+		if strings.Contains(added, "web.1: up") {
+			t.Errorf("Set(1, ...) reprinted the unchanged line 0: %q", added)
+		}
+		if !strings.Contains(added, "worker.1: starting") {
+			t.Errorf("Set(1, ...) did not print the changed line 1: %q", added)
+		}
+	})
+}
+
+func TestLiveDrawNonTTYSkipsUnchangedLines(t *testing.T) {
+	withCapturedStdout(t, func(buf *bytes.Buffer) {
+		live := &Live{lines: make([]string, 1), lastLines: make([]string, 1)}
+		live.Set(0, "web.1: up")
+		before := buf.Len()
+		live.Set(0, "web.1: up")
+		if buf.Len() != before {
+			t.Errorf("redraw with no line change should not print again, wrote %q", buf.String()[before:])
+		}
+	})
+}